@@ -0,0 +1,54 @@
+package main
+
+import "context"
+
+// DriverConfig carries everything a BackendDriver needs to dial its target
+// and shape its requests. It's passed to Setup rather than just a URL so
+// the req/resp size sweep added for the HTTP backends (clients/go) carries
+// over to every backend uniformly.
+type DriverConfig struct {
+	URL         string // target address; interpretation is driver-specific (http(s) URL, unix socket path, host:port, ...)
+	ReqBody     []byte
+	RespSize    int
+	Concurrency int  // worker count for this run; sizes connection pools so they don't bottleneck ahead of the workers using them
+	Echo        bool // when set, the server echoes ReqBody back instead of a RespSize-shaped payload, so req size drives response wire cost too
+}
+
+// BackendDriver is the seam between the generic load-generation/metrics
+// runner (runner.go) and a specific wire protocol. Implementations are
+// registered in the drivers map below and selected by the BACKEND env var.
+type BackendDriver interface {
+	// Setup dials/configures the backend. Called once per concurrency level,
+	// before warmup, and must be safe to call DoRequest concurrently after
+	// it returns.
+	Setup(cfg DriverConfig) error
+	// SetPayload updates the request body and requested response size used
+	// by subsequent DoRequest calls, without redialing. Called once per
+	// sweep-matrix cell (see runCell in main.go) so the connection opened
+	// in Setup stays warm across every req/resp size combination at a given
+	// concurrency.
+	SetPayload(reqBody []byte, respSize int)
+	// DoRequest issues one request and reports its latency and whether it
+	// succeeded. Implementations must be safe for concurrent use by
+	// multiple worker goroutines.
+	DoRequest(ctx context.Context) (latencyMs float64, ok bool)
+	// Teardown releases any connections/resources Setup acquired.
+	Teardown()
+}
+
+// driverFactories holds one constructor per BACKEND value. Each driver
+// registers itself from its own file's init(), so adding a new backend
+// never requires touching this file.
+var driverFactories = map[string]func() BackendDriver{}
+
+func registerDriver(name string, factory func() BackendDriver) {
+	driverFactories[name] = factory
+}
+
+func newDriver(backend string) BackendDriver {
+	factory, ok := driverFactories[backend]
+	if !ok {
+		return nil
+	}
+	return factory()
+}
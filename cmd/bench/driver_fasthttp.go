@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+func init() {
+	registerDriver("fasthttp", func() BackendDriver { return &fasthttpDriver{} })
+}
+
+// fasthttpDriver is the valyala/fasthttp backend - the same transport
+// clients/go/client_fasthttp.go used before this refactor.
+type fasthttpDriver struct {
+	cfg    DriverConfig
+	client *fasthttp.Client
+}
+
+func (d *fasthttpDriver) Setup(cfg DriverConfig) error {
+	d.cfg = cfg
+	d.client = &fasthttp.Client{
+		MaxConnsPerHost:     cfg.Concurrency,
+		MaxIdleConnDuration: 60 * time.Second,
+	}
+	return nil
+}
+
+func (d *fasthttpDriver) SetPayload(reqBody []byte, respSize int) {
+	d.cfg.ReqBody = reqBody
+	d.cfg.RespSize = respSize
+}
+
+func (d *fasthttpDriver) DoRequest(ctx context.Context) (float64, bool) {
+	start := time.Now()
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI(d.cfg.URL)
+	req.Header.SetMethod("POST")
+	req.Header.SetContentType("application/json")
+	req.SetBody(d.cfg.ReqBody)
+	if d.cfg.RespSize > 0 {
+		req.Header.Set("X-Resp-Size", strconv.Itoa(d.cfg.RespSize))
+	}
+	if d.cfg.Echo {
+		req.Header.Set("X-Echo", "1")
+	}
+
+	err := d.client.DoTimeout(req, resp, 10*time.Second)
+	latency := float64(time.Since(start).Microseconds()) / 1000.0
+	if err != nil || resp.StatusCode() != 200 {
+		return latency, false
+	}
+	return latency, true
+}
+
+func (d *fasthttpDriver) Teardown() {
+	d.client.CloseIdleConnections()
+}
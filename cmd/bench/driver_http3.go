@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+func init() {
+	registerDriver("http3", func() BackendDriver { return &http3Driver{} })
+}
+
+// http3Driver speaks HTTP/3 over QUIC against the server's HTTP3_PORT
+// listener. QUIC requires TLS, so InsecureSkipVerify is set - the server's
+// cert is a throwaway self-signed one regenerated on every run, and this
+// harness never leaves localhost/the test network.
+type http3Driver struct {
+	cfg       DriverConfig
+	client    *http.Client
+	transport *http3.Transport
+}
+
+func (d *http3Driver) Setup(cfg DriverConfig) error {
+	d.cfg = cfg
+	d.transport = &http3.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+	d.client = &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: d.transport,
+	}
+	return nil
+}
+
+func (d *http3Driver) SetPayload(reqBody []byte, respSize int) {
+	d.cfg.ReqBody = reqBody
+	d.cfg.RespSize = respSize
+}
+
+func (d *http3Driver) DoRequest(ctx context.Context) (float64, bool) {
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", d.cfg.URL, bytes.NewReader(d.cfg.ReqBody))
+	if err != nil {
+		return float64(time.Since(start).Microseconds()) / 1000.0, false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if d.cfg.RespSize > 0 {
+		req.Header.Set("X-Resp-Size", strconv.Itoa(d.cfg.RespSize))
+	}
+	if d.cfg.Echo {
+		req.Header.Set("X-Echo", "1")
+	}
+
+	resp, err := d.client.Do(req)
+	latency := float64(time.Since(start).Microseconds()) / 1000.0
+	if err != nil {
+		return latency, false
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	return latency, resp.StatusCode == 200
+}
+
+func (d *http3Driver) Teardown() {
+	d.transport.Close()
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func init() {
+	registerDriver("nethttp1", func() BackendDriver { return &nethttp1Driver{} })
+}
+
+// nethttp1Driver is the plain net/http, HTTP/1.1 backend - the same
+// transport clients/go/client_nethttp.go used before this refactor.
+type nethttp1Driver struct {
+	cfg    DriverConfig
+	client *http.Client
+}
+
+func (d *nethttp1Driver) Setup(cfg DriverConfig) error {
+	d.cfg = cfg
+	d.client = &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			MaxIdleConnsPerHost: cfg.Concurrency,
+			MaxConnsPerHost:     cfg.Concurrency * 2,
+		},
+	}
+	return nil
+}
+
+func (d *nethttp1Driver) SetPayload(reqBody []byte, respSize int) {
+	d.cfg.ReqBody = reqBody
+	d.cfg.RespSize = respSize
+}
+
+func (d *nethttp1Driver) DoRequest(ctx context.Context) (float64, bool) {
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", d.cfg.URL, bytes.NewReader(d.cfg.ReqBody))
+	if err != nil {
+		return float64(time.Since(start).Microseconds()) / 1000.0, false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if d.cfg.RespSize > 0 {
+		req.Header.Set("X-Resp-Size", strconv.Itoa(d.cfg.RespSize))
+	}
+	if d.cfg.Echo {
+		req.Header.Set("X-Echo", "1")
+	}
+
+	resp, err := d.client.Do(req)
+	latency := float64(time.Since(start).Microseconds()) / 1000.0
+	if err != nil {
+		return latency, false
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	return latency, resp.StatusCode == 200
+}
+
+func (d *nethttp1Driver) Teardown() {
+	d.client.CloseIdleConnections()
+}
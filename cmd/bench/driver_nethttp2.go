@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+func init() {
+	registerDriver("nethttp2", func() BackendDriver { return &nethttp2Driver{} })
+}
+
+// nethttp2Driver speaks cleartext HTTP/2 with prior knowledge (h2c) - no
+// TLS, no ALPN negotiation, just the HTTP/2 framing straight over a plain
+// TCP dial. It targets the server's H2C_PORT listener.
+type nethttp2Driver struct {
+	cfg    DriverConfig
+	client *http.Client
+}
+
+func (d *nethttp2Driver) Setup(cfg DriverConfig) error {
+	d.cfg = cfg
+	d.client = &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, addr)
+			},
+		},
+	}
+	return nil
+}
+
+func (d *nethttp2Driver) SetPayload(reqBody []byte, respSize int) {
+	d.cfg.ReqBody = reqBody
+	d.cfg.RespSize = respSize
+}
+
+func (d *nethttp2Driver) DoRequest(ctx context.Context) (float64, bool) {
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", d.cfg.URL, bytes.NewReader(d.cfg.ReqBody))
+	if err != nil {
+		return float64(time.Since(start).Microseconds()) / 1000.0, false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if d.cfg.RespSize > 0 {
+		req.Header.Set("X-Resp-Size", strconv.Itoa(d.cfg.RespSize))
+	}
+	if d.cfg.Echo {
+		req.Header.Set("X-Echo", "1")
+	}
+
+	resp, err := d.client.Do(req)
+	latency := float64(time.Since(start).Microseconds()) / 1000.0
+	if err != nil {
+		return latency, false
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	return latency, resp.StatusCode == 200
+}
+
+func (d *nethttp2Driver) Teardown() {
+	d.client.CloseIdleConnections()
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func init() {
+	registerDriver("unix", func() BackendDriver { return &unixDriver{} })
+}
+
+// unixDriver is plain net/http over a Unix domain socket instead of TCP,
+// isolating transport overhead from the network stack. cfg.URL is the
+// socket path (e.g. /tmp/bench.sock); requests are sent to a fixed
+// "http://unix/" URL since the socket, not the hostname, does the routing.
+type unixDriver struct {
+	sockPath string
+	cfg      DriverConfig
+	client   *http.Client
+}
+
+func (d *unixDriver) Setup(cfg DriverConfig) error {
+	d.sockPath = cfg.URL
+	d.cfg = cfg
+	d.client = &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", d.sockPath)
+			},
+			MaxIdleConnsPerHost: cfg.Concurrency,
+			MaxConnsPerHost:     cfg.Concurrency * 2,
+		},
+	}
+	return nil
+}
+
+func (d *unixDriver) SetPayload(reqBody []byte, respSize int) {
+	d.cfg.ReqBody = reqBody
+	d.cfg.RespSize = respSize
+}
+
+func (d *unixDriver) DoRequest(ctx context.Context) (float64, bool) {
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "http://unix/", bytes.NewReader(d.cfg.ReqBody))
+	if err != nil {
+		return float64(time.Since(start).Microseconds()) / 1000.0, false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if d.cfg.RespSize > 0 {
+		req.Header.Set("X-Resp-Size", strconv.Itoa(d.cfg.RespSize))
+	}
+	if d.cfg.Echo {
+		req.Header.Set("X-Echo", "1")
+	}
+
+	resp, err := d.client.Do(req)
+	latency := float64(time.Since(start).Microseconds()) / 1000.0
+	if err != nil {
+		return latency, false
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	return latency, resp.StatusCode == 200
+}
+
+func (d *unixDriver) Teardown() {
+	d.client.CloseIdleConnections()
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// buildPayload returns a deterministic size-byte request body, so request
+// size is controllable independently of the body's semantic content.
+func buildPayload(size int) []byte {
+	if size <= 0 {
+		return []byte(`{"msg":"hello"}`)
+	}
+	buf := make([]byte, size)
+	for i := range buf {
+		buf[i] = byte('a' + i%26)
+	}
+	return buf
+}
+
+// parseIntList parses a comma-separated list of ints, e.g. "1,8,64,256".
+// Unparseable entries are skipped.
+func parseIntList(s string) []int {
+	var out []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if v, err := strconv.Atoi(part); err == nil {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func getEnv(key, defaultVal string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return defaultVal
+}
+
+// startProfiling begins CPU profiling and execution tracing if CPU_PROFILE
+// and/or TRACE are set, returning a function that stops and closes whatever
+// was started. Intended to wrap just the test phase so warmup noise doesn't
+// pollute the profile.
+func startProfiling() func() {
+	var stopFns []func()
+
+	if path := os.Getenv("CPU_PROFILE"); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			log.Printf("could not create CPU profile: %v", err)
+		} else if err := pprof.StartCPUProfile(f); err != nil {
+			log.Printf("could not start CPU profile: %v", err)
+			f.Close()
+		} else {
+			stopFns = append(stopFns, func() {
+				pprof.StopCPUProfile()
+				f.Close()
+				log.Printf("CPU profile written to %s", path)
+			})
+		}
+	}
+
+	if path := os.Getenv("TRACE"); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			log.Printf("could not create trace output: %v", err)
+		} else if err := trace.Start(f); err != nil {
+			log.Printf("could not start trace: %v", err)
+			f.Close()
+		} else {
+			stopFns = append(stopFns, func() {
+				trace.Stop()
+				f.Close()
+				log.Printf("Trace written to %s", path)
+			})
+		}
+	}
+
+	return func() {
+		for _, stop := range stopFns {
+			stop()
+		}
+	}
+}
+
+// writeHeapProfile dumps a heap profile to MEM_PROFILE if set. Call after
+// the test phase completes.
+func writeHeapProfile() {
+	path := os.Getenv("MEM_PROFILE")
+	if path == "" {
+		return
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		log.Printf("could not create heap profile: %v", err)
+		return
+	}
+	defer f.Close()
+
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		log.Printf("could not write heap profile: %v", err)
+		return
+	}
+	log.Printf("Heap profile written to %s", path)
+}
+
+// runCell resets a warm runner's metrics and request/response shape, runs
+// the test phase for one (reqSize, respSize) combination, then prints and
+// saves the result. The underlying driver's connections are left untouched
+// so they stay warm across every cell at the same concurrency.
+func runCell(backend string, rn *runner, concurrency, reqSize, respSize, testDuration int, controlURL string) {
+	log.Printf("--- backend=%s concurrency=%d req_size=%d resp_size=%d ---", backend, concurrency, reqSize, respSize)
+
+	rn.reqBody = buildPayload(reqSize)
+	rn.respSize = respSize
+	rn.driver.SetPayload(rn.reqBody, rn.respSize)
+	rn.resetMetrics()
+	rn.duration = testDuration
+
+	// Clear the server's own Metrics/runtime_samples before this cell starts,
+	// otherwise they accumulate across the whole sweep instead of reflecting
+	// just this (reqSize, respSize) combination.
+	http.Post(controlURL+"/control/reset", "application/json", nil)
+	http.Post(controlURL+"/control/start-collection", "application/json", nil)
+
+	stopProfiling := startProfiling()
+	rn.Run()
+	stopProfiling()
+	writeHeapProfile()
+
+	http.Post(controlURL+"/control/stop-collection", "application/json", nil)
+
+	metrics := rn.GetMetrics()
+	if metrics == nil {
+		return
+	}
+
+	jsonData, _ := json.MarshalIndent(metrics, "", "  ")
+	fmt.Println(string(jsonData))
+
+	os.MkdirAll("/results", 0755)
+	outputFile := fmt.Sprintf("/results/%s_c%d_req%d_resp%d.json", backend, concurrency, reqSize, respSize)
+	os.WriteFile(outputFile, jsonData, 0644)
+	log.Printf("Results saved to %s", outputFile)
+}
+
+func availableBackends() []string {
+	names := make([]string, 0, len(driverFactories))
+	for name := range driverFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func main() {
+	backend := getEnv("BACKEND", "nethttp1")
+	// targetURL is what each driver's Setup dials: an http(s) URL for the
+	// http-family backends, a unix socket path for unix, a host:port for
+	// grpc. controlURL is always the plain HTTP/1.1 address, since every
+	// backend shares the same /control/* endpoints regardless of which
+	// transport it benchmarks.
+	controlURL := getEnv("SERVER_URL", "http://server:8080")
+	targetURL := getEnv("TARGET_URL", defaultTargetURL(backend, controlURL))
+
+	concurrencies := parseIntList(getEnv("CONCURRENCY", "8"))
+	reqSizes := parseIntList(getEnv("REQ_SIZE_BYTES", "15"))
+	respSizes := parseIntList(getEnv("RESP_SIZE_BYTES", "0"))
+	warmupDuration, _ := strconv.Atoi(getEnv("WARMUP_DURATION", "120"))
+	testDuration, _ := strconv.Atoi(getEnv("TEST_DURATION", "180"))
+	loadMode := getEnv("LOAD_MODE", "closed")
+	targetRPS, _ := strconv.ParseFloat(getEnv("TARGET_RPS", "0"), 64)
+	arrivalDist := getEnv("ARRIVAL", "uniform")
+	maxPending, _ := strconv.ParseInt(getEnv("MAX_PENDING", strconv.Itoa(defaultMaxPendingPerWorker)), 10, 64)
+	echo := getEnv("ECHO", "0") == "1"
+	if rate, _ := strconv.Atoi(getEnv("MEM_PROFILE_RATE", "0")); rate > 0 {
+		runtime.MemProfileRate = rate
+	}
+
+	log.Printf("Starting benchmark: backend=%s (available: %v)", backend, availableBackends())
+	log.Printf("Concurrency sweep: %v, Req sizes: %v, Resp sizes: %v", concurrencies, reqSizes, respSizes)
+	log.Printf("Warmup: %ds, Test: %ds", warmupDuration, testDuration)
+	if loadMode == "open" {
+		log.Printf("Load mode: open, target RPS: %.0f, arrival: %s", targetRPS, arrivalDist)
+	}
+	if echo {
+		log.Printf("Echo mode: on (RESP_SIZE_BYTES ignored, response mirrors the request body)")
+	}
+	if backend == "grpc" {
+		log.Printf("WARNING: backend=grpc uses a JSON codec (pkg/pingpb), not protobuf - " +
+			"these numbers measure gRPC framing with JSON payloads, not gRPC+protobuf, " +
+			"and should not be compared as if they were")
+	}
+
+	for _, concurrency := range concurrencies {
+		driver := newDriver(backend)
+		if driver == nil {
+			log.Fatalf("unknown BACKEND %q; available: %v", backend, availableBackends())
+		}
+
+		rn := newRunner(backend, driver, concurrency, warmupDuration)
+		rn.loadMode = loadMode
+		rn.targetRPS = targetRPS
+		rn.arrivalDist = arrivalDist
+		rn.maxPending = maxPending
+
+		if err := driver.Setup(DriverConfig{URL: targetURL, ReqBody: rn.reqBody, Concurrency: concurrency, Echo: echo}); err != nil {
+			log.Fatalf("backend %s: setup failed: %v", backend, err)
+		}
+
+		log.Printf("Phase 1: Warmup (concurrency=%d)...", concurrency)
+		rn.Run()
+		log.Printf("Warmup completed: %d requests", atomic.LoadInt64(&rn.count)+atomic.LoadInt64(&rn.failures))
+
+		for _, reqSize := range reqSizes {
+			for _, respSize := range respSizes {
+				runCell(backend, rn, concurrency, reqSize, respSize, testDuration, controlURL)
+			}
+		}
+
+		driver.Teardown()
+	}
+}
+
+// defaultTargetURL picks a sensible TARGET_URL for each backend when one
+// isn't given explicitly, based on controlURL's host and the server's
+// per-protocol listener env var conventions (see server/transports.go).
+func defaultTargetURL(backend, controlURL string) string {
+	switch backend {
+	case "nethttp2":
+		return "http://" + hostOnly(controlURL) + ":" + getEnv("H2C_PORT", "8082") + "/"
+	case "http3":
+		return "https://" + hostOnly(controlURL) + ":" + getEnv("HTTP3_PORT", "8083") + "/"
+	case "grpc":
+		return hostOnly(controlURL) + ":" + getEnv("GRPC_PORT", "8084")
+	case "unix":
+		return getEnv("UNIX_SOCKET_PATH", "/tmp/bench.sock")
+	default:
+		return controlURL
+	}
+}
+
+func hostOnly(url string) string {
+	s := strings.TrimPrefix(url, "http://")
+	s = strings.TrimPrefix(s, "https://")
+	if idx := strings.Index(s, ":"); idx >= 0 {
+		s = s[:idx]
+	}
+	if idx := strings.Index(s, "/"); idx >= 0 {
+		s = s[:idx]
+	}
+	return s
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	hdrhistogram "github.com/HdrHistogram/hdrhistogram-go"
+)
+
+// Latencies are recorded into the histogram in microseconds; 1us..60s covers
+// everything we expect to see without clipping, at 3 significant figures
+// (~200KB per histogram regardless of sample count).
+const (
+	histogramMinValue   = 1
+	histogramMaxValue   = 60 * 1000 * 1000
+	histogramSigFigures = 3
+)
+
+// defaultMaxPendingPerWorker bounds how many open-loop requests a single
+// worker's arrival process may have in flight at once. Past this, the
+// system can no longer keep up with the target rate, so further scheduled
+// sends are counted as saturation failures instead of queuing unbounded.
+// Overridable via the MAX_PENDING env var (see main.go).
+const defaultMaxPendingPerWorker = 1000
+
+// Metrics is the JSON result written per sweep cell, one per backend. Its
+// shape matches the one clients/go/client_fasthttp.go and
+// client_nethttp.go used to produce, so cmd/benchcompare keeps working
+// unchanged.
+type Metrics struct {
+	Library            string  `json:"library"`
+	Language           string  `json:"language"`
+	Concurrency        int     `json:"concurrency"`
+	Duration           int     `json:"duration"`
+	TotalRequests      int     `json:"total_requests"`
+	SuccessfulRequests int     `json:"successful_requests"`
+	FailedRequests     int     `json:"failed_requests"`
+	ErrorRate          float64 `json:"error_rate"`
+	Throughput         float64 `json:"throughput"`
+	LatencyAvgMs       float64 `json:"latency_avg_ms"`
+	LatencyP50Ms       float64 `json:"latency_p50_ms"`
+	LatencyP95Ms       float64 `json:"latency_p95_ms"`
+	LatencyP99Ms       float64 `json:"latency_p99_ms"`
+	LatencyP999Ms      float64 `json:"latency_p999_ms"`
+	LatencyMinMs       float64 `json:"latency_min_ms"`
+	LatencyMaxMs       float64 `json:"latency_max_ms"`
+	LoadMode           string  `json:"load_mode"`
+	TargetRPS          float64 `json:"target_rps,omitempty"`
+	SaturatedRequests  int     `json:"saturated_requests"`
+	ReqSizeBytes       int     `json:"req_size_bytes"`
+	RespSizeBytes      int     `json:"resp_size_bytes"`
+}
+
+// runner drives a BackendDriver through closed- or open-loop load
+// generation, recording results the same way the two retired per-library
+// clients did. One runner is built per concurrency level and reused (via
+// resetMetrics) across every req/resp size cell, so the driver's
+// connections stay warm within a size bucket.
+type runner struct {
+	backend     string
+	driver      BackendDriver
+	concurrency int
+	duration    int
+	loadMode    string  // "closed" (default) or "open"
+	targetRPS   float64 // aggregate rate across all workers, open mode only
+	arrivalDist string  // "uniform" (default, fixed interval) or "poisson"
+	maxPending  int64   // per-worker in-flight cap, open mode only
+	reqBody     []byte
+	respSize    int
+	histogram   *hdrhistogram.Histogram
+	histMu      sync.Mutex // guards merges/records against the shared histogram
+	count       int64      // atomic: successful requests
+	failures    int64      // atomic
+	saturated   int64      // atomic: scheduled sends dropped for exceeding maxPending
+	sumMicros   int64      // atomic: sum of successful latencies, for the mean
+	minMicros   int64      // atomic
+	maxMicros   int64      // atomic
+}
+
+func newRunner(backend string, driver BackendDriver, concurrency, duration int) *runner {
+	return &runner{
+		backend:     backend,
+		driver:      driver,
+		concurrency: concurrency,
+		duration:    duration,
+		loadMode:    "closed",
+		arrivalDist: "uniform",
+		maxPending:  defaultMaxPendingPerWorker,
+		reqBody:     []byte(`{"msg":"hello"}`),
+		histogram:   hdrhistogram.New(histogramMinValue, histogramMaxValue, histogramSigFigures),
+		minMicros:   math.MaxInt64,
+	}
+}
+
+// resetMetrics clears the accumulated counters/histogram between matrix
+// cells while leaving the driver (and its connections) untouched.
+func (rn *runner) resetMetrics() {
+	rn.histogram = hdrhistogram.New(histogramMinValue, histogramMaxValue, histogramSigFigures)
+	rn.count = 0
+	rn.failures = 0
+	rn.saturated = 0
+	rn.sumMicros = 0
+	rn.minMicros = math.MaxInt64
+	rn.maxMicros = 0
+}
+
+// recordLatency folds a successful request's latency into the atomic
+// count/sum/min/max counters. Called from each worker's hot loop, so it must
+// stay lock-free.
+func (rn *runner) recordLatency(micros int64) {
+	atomic.AddInt64(&rn.count, 1)
+	atomic.AddInt64(&rn.sumMicros, micros)
+
+	for {
+		cur := atomic.LoadInt64(&rn.minMicros)
+		if micros >= cur || atomic.CompareAndSwapInt64(&rn.minMicros, cur, micros) {
+			break
+		}
+	}
+	for {
+		cur := atomic.LoadInt64(&rn.maxMicros)
+		if micros <= cur || atomic.CompareAndSwapInt64(&rn.maxMicros, cur, micros) {
+			break
+		}
+	}
+}
+
+// workerClosed runs the traditional closed-loop generator: send, wait for the
+// response, send the next one. Under load this suffers coordinated omission
+// — a slow response delays the next request, hiding tail latency — so it's
+// only appropriate when LOAD_MODE=closed is explicitly requested.
+func (rn *runner) workerClosed(ctx context.Context, stopTime time.Time, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	// Each worker records into its own histogram to avoid contending on a
+	// shared one in the hot loop, merging into rn.histogram only once on exit.
+	localHist := hdrhistogram.New(histogramMinValue, histogramMaxValue, histogramSigFigures)
+	var localFailures int64
+
+	for time.Now().Before(stopTime) {
+		latencyMs, success := rn.driver.DoRequest(ctx)
+		micros := int64(latencyMs * 1000)
+		if success {
+			localHist.RecordValue(micros)
+			rn.recordLatency(micros)
+		} else {
+			localFailures++
+		}
+	}
+
+	atomic.AddInt64(&rn.failures, localFailures)
+
+	rn.histMu.Lock()
+	rn.histogram.Merge(localHist)
+	rn.histMu.Unlock()
+}
+
+// workerOpen schedules request sends from an arrival process independent of
+// response completion, avoiding coordinated omission. Each scheduled send
+// fires in its own goroutine so a slow response can never delay the next
+// send; latency is measured against the *intended* send time, not when the
+// request actually left, so stalls show up as latency rather than being
+// absorbed. If more than maxPending sends are still outstanding, the
+// schedule has fallen behind the target rate and the slot is counted as a
+// saturation failure rather than queued indefinitely.
+func (rn *runner) workerOpen(ctx context.Context, stopTime time.Time, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	perWorkerRPS := rn.targetRPS / float64(rn.concurrency)
+	if perWorkerRPS <= 0 {
+		return
+	}
+	meanInterval := time.Duration(float64(time.Second) / perWorkerRPS)
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	var inFlight sync.WaitGroup
+	var pending int64
+
+	// Like workerClosed, each worker records into its own histogram instead
+	// of rn.histogram directly: workerOpen fires one goroutine per scheduled
+	// send, so at high target RPS a single shared rn.histMu would be
+	// contended by every in-flight send across every worker. Sharding to one
+	// histogram (and lock) per worker, merged once on exit, bounds
+	// contention to this worker's own in-flight sends instead.
+	localHist := hdrhistogram.New(histogramMinValue, histogramMaxValue, histogramSigFigures)
+	var localMu sync.Mutex
+	var localFailures int64
+
+	for intended := time.Now(); intended.Before(stopTime); {
+		if now := time.Now(); intended.After(now) {
+			time.Sleep(intended.Sub(now))
+		}
+
+		if atomic.LoadInt64(&pending) >= rn.maxPending {
+			atomic.AddInt64(&rn.saturated, 1)
+			atomic.AddInt64(&rn.failures, 1)
+		} else {
+			atomic.AddInt64(&pending, 1)
+			inFlight.Add(1)
+			sendAt := intended
+			go func() {
+				defer inFlight.Done()
+				defer atomic.AddInt64(&pending, -1)
+
+				_, success := rn.driver.DoRequest(ctx)
+
+				micros := time.Since(sendAt).Microseconds()
+				if success {
+					rn.recordLatency(micros)
+					localMu.Lock()
+					localHist.RecordValue(micros)
+					localMu.Unlock()
+				} else {
+					atomic.AddInt64(&localFailures, 1)
+				}
+			}()
+		}
+
+		if rn.arrivalDist == "poisson" {
+			intended = intended.Add(time.Duration(rng.ExpFloat64() * float64(meanInterval)))
+		} else {
+			intended = intended.Add(meanInterval)
+		}
+	}
+
+	inFlight.Wait()
+
+	atomic.AddInt64(&rn.failures, atomic.LoadInt64(&localFailures))
+
+	rn.histMu.Lock()
+	rn.histogram.Merge(localHist)
+	rn.histMu.Unlock()
+}
+
+func (rn *runner) Run() {
+	ctx := context.Background()
+	stopTime := time.Now().Add(time.Duration(rn.duration) * time.Second)
+	var wg sync.WaitGroup
+
+	for i := 0; i < rn.concurrency; i++ {
+		wg.Add(1)
+		if rn.loadMode == "open" {
+			go rn.workerOpen(ctx, stopTime, &wg)
+		} else {
+			go rn.workerClosed(ctx, stopTime, &wg)
+		}
+	}
+
+	wg.Wait()
+}
+
+func (rn *runner) GetMetrics() *Metrics {
+	count := atomic.LoadInt64(&rn.count)
+	if count == 0 {
+		return nil
+	}
+	failures := atomic.LoadInt64(&rn.failures)
+	sumMicros := atomic.LoadInt64(&rn.sumMicros)
+
+	totalRequests := count + failures
+
+	return &Metrics{
+		Library:            rn.backend,
+		Language:           "go",
+		Concurrency:        rn.concurrency,
+		Duration:           rn.duration,
+		TotalRequests:      int(totalRequests),
+		SuccessfulRequests: int(count),
+		FailedRequests:     int(failures),
+		ErrorRate:          float64(failures) / float64(totalRequests) * 100,
+		Throughput:         float64(totalRequests) / float64(rn.duration),
+		LatencyAvgMs:       microsToMs(sumMicros) / float64(count),
+		LatencyP50Ms:       microsToMs(rn.histogram.ValueAtQuantile(50)),
+		LatencyP95Ms:       microsToMs(rn.histogram.ValueAtQuantile(95)),
+		LatencyP99Ms:       microsToMs(rn.histogram.ValueAtQuantile(99)),
+		LatencyP999Ms:      microsToMs(rn.histogram.ValueAtQuantile(99.9)),
+		LatencyMinMs:       microsToMs(atomic.LoadInt64(&rn.minMicros)),
+		LatencyMaxMs:       microsToMs(atomic.LoadInt64(&rn.maxMicros)),
+		LoadMode:           rn.loadMode,
+		TargetRPS:          rn.targetRPS,
+		SaturatedRequests:  int(atomic.LoadInt64(&rn.saturated)),
+		ReqSizeBytes:       len(rn.reqBody),
+		RespSizeBytes:      rn.respSize,
+	}
+}
+
+func microsToMs(micros int64) float64 {
+	return float64(micros) / 1000.0
+}
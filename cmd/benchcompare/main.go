@@ -0,0 +1,189 @@
+// cmd/benchcompare compares two directories of benchmark result JSON files
+// (as produced by clients/go/client_fasthttp.go and client_nethttp.go) and
+// reports throughput/latency deltas, flagging regressions for CI gating.
+//
+// Usage:
+//
+//	benchcompare -base basePerf/ -cur curPerf/ [-format text|markdown|json]
+//	             [-throughput-threshold 5] [-p99-threshold 10]
+//
+// Exits non-zero if any matched result regresses past the thresholds.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// result is the subset of the client Metrics JSON that benchcompare cares
+// about; unknown fields are ignored by encoding/json.
+type result struct {
+	Library      string  `json:"library"`
+	Concurrency  int     `json:"concurrency"`
+	Throughput   float64 `json:"throughput"`
+	LatencyP50Ms float64 `json:"latency_p50_ms"`
+	LatencyP95Ms float64 `json:"latency_p95_ms"`
+	LatencyP99Ms float64 `json:"latency_p99_ms"`
+}
+
+// comparison is one matched base-vs-current pair, keyed by filename.
+type comparison struct {
+	Name               string  `json:"name"`
+	Base               result  `json:"base"`
+	Current            result  `json:"current"`
+	ThroughputDelta    float64 `json:"throughput_delta"`
+	ThroughputDeltaPct float64 `json:"throughput_delta_pct"`
+	P50Delta           float64 `json:"p50_delta_ms"`
+	P50DeltaPct        float64 `json:"p50_delta_pct"`
+	P95Delta           float64 `json:"p95_delta_ms"`
+	P95DeltaPct        float64 `json:"p95_delta_pct"`
+	P99Delta           float64 `json:"p99_delta_ms"`
+	P99DeltaPct        float64 `json:"p99_delta_pct"`
+	Regression         bool    `json:"regression"`
+}
+
+func main() {
+	baseDir := flag.String("base", "basePerf", "directory of baseline result JSON files")
+	curDir := flag.String("cur", "curPerf", "directory of current result JSON files")
+	format := flag.String("format", "text", "output format: text, markdown, json")
+	throughputThresholdPct := flag.Float64("throughput-threshold", 5.0, "flag a regression when throughput drops by more than this percent")
+	p99ThresholdPct := flag.Float64("p99-threshold", 10.0, "flag a regression when p99 latency increases by more than this percent")
+	flag.Parse()
+
+	baseResults, err := loadResults(*baseDir)
+	if err != nil {
+		log.Fatalf("loading base results from %s: %v", *baseDir, err)
+	}
+	curResults, err := loadResults(*curDir)
+	if err != nil {
+		log.Fatalf("loading current results from %s: %v", *curDir, err)
+	}
+
+	comparisons := compare(baseResults, curResults, *throughputThresholdPct, *p99ThresholdPct)
+
+	switch *format {
+	case "json":
+		printJSON(comparisons)
+	case "markdown":
+		printMarkdown(comparisons)
+	default:
+		printText(comparisons)
+	}
+
+	for _, c := range comparisons {
+		if c.Regression {
+			os.Exit(1)
+		}
+	}
+}
+
+// loadResults reads every *.json file in dir into a name -> result map,
+// where name is the filename without its extension.
+func loadResults(dir string) (map[string]result, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]result)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", entry.Name(), err)
+		}
+
+		var r result
+		if err := json.Unmarshal(data, &r); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", entry.Name(), err)
+		}
+
+		name := strings.TrimSuffix(entry.Name(), ".json")
+		results[name] = r
+	}
+	return results, nil
+}
+
+// compare matches base and current results by name and computes deltas,
+// sorted by name for stable output.
+func compare(base, cur map[string]result, throughputThresholdPct, p99ThresholdPct float64) []comparison {
+	names := make([]string, 0, len(base))
+	for name := range base {
+		if _, ok := cur[name]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	comparisons := make([]comparison, 0, len(names))
+	for _, name := range names {
+		b, c := base[name], cur[name]
+
+		throughputDelta := pctChange(b.Throughput, c.Throughput)
+		p99Delta := pctChange(b.LatencyP99Ms, c.LatencyP99Ms)
+
+		comparisons = append(comparisons, comparison{
+			Name:               name,
+			Base:               b,
+			Current:            c,
+			ThroughputDelta:    c.Throughput - b.Throughput,
+			ThroughputDeltaPct: throughputDelta,
+			P50Delta:           c.LatencyP50Ms - b.LatencyP50Ms,
+			P50DeltaPct:        pctChange(b.LatencyP50Ms, c.LatencyP50Ms),
+			P95Delta:           c.LatencyP95Ms - b.LatencyP95Ms,
+			P95DeltaPct:        pctChange(b.LatencyP95Ms, c.LatencyP95Ms),
+			P99Delta:           c.LatencyP99Ms - b.LatencyP99Ms,
+			P99DeltaPct:        p99Delta,
+			Regression:         throughputDelta < -throughputThresholdPct || p99Delta > p99ThresholdPct,
+		})
+	}
+	return comparisons
+}
+
+func pctChange(base, cur float64) float64 {
+	if base == 0 {
+		return 0
+	}
+	return (cur - base) / base * 100
+}
+
+func printText(comparisons []comparison) {
+	for _, c := range comparisons {
+		status := "OK"
+		if c.Regression {
+			status = "REGRESSION"
+		}
+		fmt.Printf("%-30s throughput %+.1f%% (%+.1f rps)  p50 %+.1f%% (%+.2fms)  p95 %+.1f%% (%+.2fms)  p99 %+.1f%% (%+.2fms)  [%s]\n",
+			c.Name, c.ThroughputDeltaPct, c.ThroughputDelta,
+			c.P50DeltaPct, c.P50Delta, c.P95DeltaPct, c.P95Delta, c.P99DeltaPct, c.P99Delta, status)
+	}
+}
+
+func printMarkdown(comparisons []comparison) {
+	fmt.Println("| Result | Library | Concurrency | Throughput Δ | p50 Δ | p95 Δ | p99 Δ | Status |")
+	fmt.Println("|---|---|---|---|---|---|---|---|")
+	for _, c := range comparisons {
+		status := "OK"
+		if c.Regression {
+			status = "⚠️ REGRESSION"
+		}
+		fmt.Printf("| %s | %s | %d | %+.1f%% (%+.1f rps) | %+.1f%% (%+.2fms) | %+.1f%% (%+.2fms) | %+.1f%% (%+.2fms) | %s |\n",
+			c.Name, c.Current.Library, c.Current.Concurrency,
+			c.ThroughputDeltaPct, c.ThroughputDelta,
+			c.P50DeltaPct, c.P50Delta, c.P95DeltaPct, c.P95Delta, c.P99DeltaPct, c.P99Delta, status)
+	}
+}
+
+func printJSON(comparisons []comparison) {
+	jsonData, _ := json.MarshalIndent(comparisons, "", "  ")
+	fmt.Println(string(jsonData))
+}
@@ -0,0 +1,115 @@
+package main
+
+import "testing"
+
+func TestPctChange(t *testing.T) {
+	cases := []struct {
+		name      string
+		base, cur float64
+		wantPct   float64
+	}{
+		{"zero baseline", 0, 100, 0},
+		{"zero baseline, zero current", 0, 0, 0},
+		{"no change", 100, 100, 0},
+		{"increase", 100, 150, 50},
+		{"decrease", 100, 50, -50},
+		{"negative baseline", -100, -50, -50},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := pctChange(tc.base, tc.cur)
+			if got != tc.wantPct {
+				t.Errorf("pctChange(%v, %v) = %v, want %v", tc.base, tc.cur, got, tc.wantPct)
+			}
+		})
+	}
+}
+
+func TestCompareMatchesByName(t *testing.T) {
+	base := map[string]result{
+		"a": {Throughput: 100, LatencyP99Ms: 10},
+		"b": {Throughput: 200, LatencyP99Ms: 20},
+	}
+	cur := map[string]result{
+		"a": {Throughput: 100, LatencyP99Ms: 10},
+		// "b" missing from cur, "c" missing from base - neither should appear.
+		"c": {Throughput: 300, LatencyP99Ms: 30},
+	}
+
+	comparisons := compare(base, cur, 5, 10)
+
+	if len(comparisons) != 1 {
+		t.Fatalf("got %d comparisons, want 1 (only names present in both sides): %+v", len(comparisons), comparisons)
+	}
+	if comparisons[0].Name != "a" {
+		t.Fatalf("got comparison for %q, want %q", comparisons[0].Name, "a")
+	}
+}
+
+func TestCompareDeltas(t *testing.T) {
+	base := map[string]result{
+		"cell": {Throughput: 1000, LatencyP50Ms: 10, LatencyP95Ms: 20, LatencyP99Ms: 30},
+	}
+	cur := map[string]result{
+		"cell": {Throughput: 900, LatencyP50Ms: 11, LatencyP95Ms: 22, LatencyP99Ms: 36},
+	}
+
+	comparisons := compare(base, cur, 5, 10)
+	if len(comparisons) != 1 {
+		t.Fatalf("got %d comparisons, want 1", len(comparisons))
+	}
+	c := comparisons[0]
+
+	if c.ThroughputDelta != -100 {
+		t.Errorf("ThroughputDelta = %v, want -100", c.ThroughputDelta)
+	}
+	if c.ThroughputDeltaPct != -10 {
+		t.Errorf("ThroughputDeltaPct = %v, want -10", c.ThroughputDeltaPct)
+	}
+	if c.P99Delta != 6 {
+		t.Errorf("P99Delta = %v, want 6", c.P99Delta)
+	}
+	if c.P99DeltaPct != 20 {
+		t.Errorf("P99DeltaPct = %v, want 20", c.P99DeltaPct)
+	}
+}
+
+func TestCompareRegressionThresholdBoundary(t *testing.T) {
+	// Throughput threshold is 5%: a drop of exactly 5% should NOT regress
+	// (strict >), a drop past it should.
+	cases := []struct {
+		name           string
+		baseThroughput float64
+		curThroughput  float64
+		baseP99        float64
+		curP99         float64
+		wantRegression bool
+	}{
+		{"throughput drop exactly at threshold", 100, 95, 10, 10, false},
+		{"throughput drop past threshold", 100, 94, 10, 10, true},
+		{"p99 increase exactly at threshold", 100, 100, 10, 11, false},
+		{"p99 increase past threshold", 100, 100, 10, 11.1, true},
+		{"no change, no regression", 100, 100, 10, 10, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			base := map[string]result{"cell": {Throughput: tc.baseThroughput, LatencyP99Ms: tc.baseP99}}
+			cur := map[string]result{"cell": {Throughput: tc.curThroughput, LatencyP99Ms: tc.curP99}}
+
+			comparisons := compare(base, cur, 5, 10)
+			if len(comparisons) != 1 {
+				t.Fatalf("got %d comparisons, want 1", len(comparisons))
+			}
+			if comparisons[0].Regression != tc.wantRegression {
+				t.Errorf("Regression = %v, want %v", comparisons[0].Regression, tc.wantRegression)
+			}
+		})
+	}
+}
+
+func TestCompareEmptyInputs(t *testing.T) {
+	comparisons := compare(map[string]result{}, map[string]result{}, 5, 10)
+	if len(comparisons) != 0 {
+		t.Fatalf("got %d comparisons, want 0", len(comparisons))
+	}
+}
@@ -0,0 +1,29 @@
+package pingpb
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+func init() {
+	encoding.RegisterCodec(JSONCodec{})
+}
+
+// JSONCodec implements grpc/encoding.Codec using encoding/json instead of
+// protobuf. Registered under the name "json" so both client and server can
+// select it with grpc.CallContentSubtype("json") / grpc.ForceServerCodec,
+// skipping the protoc-gen-go-grpc step entirely for this internal service.
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (JSONCodec) Name() string {
+	return "json"
+}
@@ -0,0 +1,37 @@
+// Package pingpb defines the wire messages for the bench harness's gRPC
+// Ping service. Normally these would be generated by protoc from ping.proto
+// (see proto/ping.proto), but the service only ever talks to itself across
+// this repo's own client and server, so it uses a plain JSON gRPC codec
+// (see grpcjson.Codec) instead of pulling in the full protobuf toolchain.
+//
+// CAVEAT: this means the "grpc" backend measures gRPC's HTTP/2 framing and
+// call plumbing with JSON payloads, NOT real protobuf marshaling. Its
+// numbers are not representative of gRPC+protobuf's typical encoding
+// advantage and should not be quoted as "gRPC performance" against other
+// tools that do use protobuf.
+package pingpb
+
+// PingRequest is the unary request for PingService.Ping. Payload lets the
+// caller control request size the same way the other backends do; RespSize
+// asks the server for a response of that many bytes, mirroring the
+// X-Resp-Size header used by the HTTP backends. Echo mirrors the X-Echo
+// header: when set, the server echoes Payload back instead of sizing the
+// response from RespSize.
+type PingRequest struct {
+	Payload  []byte `json:"payload"`
+	RespSize int32  `json:"resp_size"`
+	Echo     bool   `json:"echo"`
+}
+
+// PingResponse carries the server's reply payload.
+type PingResponse struct {
+	Payload []byte `json:"payload"`
+}
+
+// ServiceName is the fully-qualified gRPC service name used to register and
+// dial PingService.
+const ServiceName = "pingpb.PingService"
+
+// PingMethod is the unary method name, combined with ServiceName to form the
+// gRPC method path "/pingpb.PingService/Ping".
+const PingMethod = "Ping"
@@ -2,95 +2,425 @@
 package main
 
 import (
+    "bytes"
     "encoding/json"
+    "io"
     "log"
+    "math"
     "net/http"
     "os"
+    "runtime"
+    "runtime/pprof"
+    "strconv"
     "sync"
     "sync/atomic"
     "time"
+
+    hdrhistogram "github.com/HdrHistogram/hdrhistogram-go"
+)
+
+// Latencies are recorded into the histogram in microseconds; 1us..60s covers
+// everything we expect to see without clipping, at 3 significant figures
+// (~200KB per histogram regardless of sample count).
+const (
+    histogramMinValue   = 1
+    histogramMaxValue   = 60 * 1000 * 1000
+    histogramSigFigures = 3
 )
 
 type Metrics struct {
-    TotalRequests   int64     `json:"total_requests"`
-    TotalErrors     int64     `json:"total_errors"`
-    Latencies       []float64 `json:"latencies_ms"`
-    StartTime       time.Time `json:"start_time"`
-    mu              sync.Mutex
+    TotalRequests  int64           `json:"total_requests"`
+    TotalErrors    int64           `json:"total_errors"`
+    LatencyMinMs   float64         `json:"latency_min_ms"`
+    LatencyMaxMs   float64         `json:"latency_max_ms"`
+    LatencyAvgMs   float64         `json:"latency_avg_ms"`
+    LatencyP50Ms   float64         `json:"latency_p50_ms"`
+    LatencyP95Ms   float64         `json:"latency_p95_ms"`
+    LatencyP99Ms   float64         `json:"latency_p99_ms"`
+    LatencyP999Ms  float64         `json:"latency_p999_ms"`
+    StartTime      time.Time       `json:"start_time"`
+    RuntimeSamples []RuntimeSample `json:"runtime_samples,omitempty"`
+
+    histogram      *hdrhistogram.Histogram
+    histMu         sync.Mutex // guards histogram.RecordValue/Merge only
+    sumMicros      int64      // atomic
+    minMicros      int64      // atomic
+    maxMicros      int64      // atomic
+    runtimeMu      sync.Mutex // guards runtimeSamples; sampled at low frequency, off the hot path
+    runtimeSamples []RuntimeSample
+}
+
+// RuntimeSample is a single point-in-time snapshot of Go runtime health,
+// taken periodically during the collection phase so results can be
+// correlated with GC pressure.
+type RuntimeSample struct {
+    TimestampMs   int64   `json:"timestamp_ms"`
+    HeapAllocMB   float64 `json:"heap_alloc_mb"`
+    HeapObjects   uint64  `json:"heap_objects"`
+    NumGC         uint32  `json:"num_gc"`
+    PauseTotalMs  float64 `json:"pause_total_ms"`
+    GCCPUFraction float64 `json:"gc_cpu_fraction"`
+    NumGoroutine  int     `json:"num_goroutine"`
+}
+
+// record folds an observed latency into the metrics. Count/sum/min/max are
+// atomic so only the histogram record itself needs a lock, keeping the
+// critical section O(1) regardless of request volume.
+func (m *Metrics) record(micros int64) {
+    atomic.AddInt64(&m.TotalRequests, 1)
+    atomic.AddInt64(&m.sumMicros, micros)
+
+    for {
+        cur := atomic.LoadInt64(&m.minMicros)
+        if micros >= cur || atomic.CompareAndSwapInt64(&m.minMicros, cur, micros) {
+            break
+        }
+    }
+    for {
+        cur := atomic.LoadInt64(&m.maxMicros)
+        if micros <= cur || atomic.CompareAndSwapInt64(&m.maxMicros, cur, micros) {
+            break
+        }
+    }
+
+    m.histMu.Lock()
+    m.histogram.RecordValue(micros)
+    m.histMu.Unlock()
+}
+
+// setStartTime and startTime guard StartTime with runtimeMu: it's written
+// from /control/start-collection and /control/reset, and read from the
+// runtime sampler ticker and snapshot, none of which share a goroutine.
+func (m *Metrics) setStartTime(t time.Time) {
+    m.runtimeMu.Lock()
+    m.StartTime = t
+    m.runtimeMu.Unlock()
+}
+
+func (m *Metrics) startTime() time.Time {
+    m.runtimeMu.Lock()
+    defer m.runtimeMu.Unlock()
+    return m.StartTime
+}
+
+// sampleRuntime records one RuntimeSample. Called from a low-frequency
+// ticker, never from the request path.
+func (m *Metrics) sampleRuntime() {
+    var ms runtime.MemStats
+    runtime.ReadMemStats(&ms)
+
+    sample := RuntimeSample{
+        TimestampMs:   time.Since(m.startTime()).Milliseconds(),
+        HeapAllocMB:   float64(ms.HeapAlloc) / (1024 * 1024),
+        HeapObjects:   ms.HeapObjects,
+        NumGC:         ms.NumGC,
+        PauseTotalMs:  float64(ms.PauseTotalNs) / 1e6,
+        GCCPUFraction: ms.GCCPUFraction,
+        NumGoroutine:  runtime.NumGoroutine(),
+    }
+
+    m.runtimeMu.Lock()
+    m.runtimeSamples = append(m.runtimeSamples, sample)
+    m.runtimeMu.Unlock()
+}
+
+// snapshot renders the current counters/histogram into the JSON-serializable
+// percentile fields. Called only from /control/metrics, off the hot path.
+func (m *Metrics) snapshot() *Metrics {
+    count := atomic.LoadInt64(&m.TotalRequests)
+
+    m.runtimeMu.Lock()
+    samples := make([]RuntimeSample, len(m.runtimeSamples))
+    copy(samples, m.runtimeSamples)
+    m.runtimeMu.Unlock()
+
+    out := &Metrics{
+        TotalRequests:  count,
+        TotalErrors:    atomic.LoadInt64(&m.TotalErrors),
+        StartTime:      m.startTime(),
+        RuntimeSamples: samples,
+    }
+    if count == 0 {
+        return out
+    }
+
+    m.histMu.Lock()
+    p50 := m.histogram.ValueAtQuantile(50)
+    p95 := m.histogram.ValueAtQuantile(95)
+    p99 := m.histogram.ValueAtQuantile(99)
+    p999 := m.histogram.ValueAtQuantile(99.9)
+    m.histMu.Unlock()
+
+    out.LatencyMinMs = microsToMs(atomic.LoadInt64(&m.minMicros))
+    out.LatencyMaxMs = microsToMs(atomic.LoadInt64(&m.maxMicros))
+    out.LatencyAvgMs = microsToMs(atomic.LoadInt64(&m.sumMicros)) / float64(count)
+    out.LatencyP50Ms = microsToMs(p50)
+    out.LatencyP95Ms = microsToMs(p95)
+    out.LatencyP99Ms = microsToMs(p99)
+    out.LatencyP999Ms = microsToMs(p999)
+    return out
+}
+
+func microsToMs(micros int64) float64 {
+    return float64(micros) / 1000.0
+}
+
+// reset zeroes the counters and swaps in a fresh histogram in place, so
+// callers holding the package-level metrics pointer keep observing it.
+func (m *Metrics) reset() {
+    m.histMu.Lock()
+    m.histogram = hdrhistogram.New(histogramMinValue, histogramMaxValue, histogramSigFigures)
+    m.histMu.Unlock()
+
+    atomic.StoreInt64(&m.TotalRequests, 0)
+    atomic.StoreInt64(&m.TotalErrors, 0)
+    atomic.StoreInt64(&m.sumMicros, 0)
+    atomic.StoreInt64(&m.minMicros, math.MaxInt64)
+    atomic.StoreInt64(&m.maxMicros, 0)
+    m.setStartTime(time.Now())
+
+    m.runtimeMu.Lock()
+    m.runtimeSamples = nil
+    m.runtimeMu.Unlock()
+}
+
+func newMetrics() *Metrics {
+    return &Metrics{
+        StartTime: time.Now(),
+        histogram: hdrhistogram.New(histogramMinValue, histogramMaxValue, histogramSigFigures),
+        minMicros: math.MaxInt64,
+    }
 }
 
 var (
-    metrics        = &Metrics{StartTime: time.Now()}
+    metrics        = newMetrics()
     collectMetrics int32 // 0 = warmup, 1 = collecting
+
+    profileMu        sync.Mutex
+    profileBuf       *bytes.Buffer
+    profileType      string
+    profileStop      *time.Timer
+    completedProfile []byte // set when the `seconds` auto-stop fires, served by the next /control/profile/stop
 )
 
+// startRuntimeSampler samples runtime.MemStats/NumGoroutine at the given
+// interval for as long as collectMetrics is set, so results can be
+// correlated with GC pressure over the run.
+func startRuntimeSampler(interval time.Duration) {
+    go func() {
+        ticker := time.NewTicker(interval)
+        defer ticker.Stop()
+        for range ticker.C {
+            if atomic.LoadInt32(&collectMetrics) == 1 {
+                metrics.sampleRuntime()
+            }
+        }
+    }()
+}
+
+// stopProfileLocked finalizes whatever profile is in progress and returns
+// its pprof-format bytes. Callers must hold profileMu.
+func stopProfileLocked() []byte {
+    if profileStop != nil {
+        profileStop.Stop()
+        profileStop = nil
+    }
+
+    buf := profileBuf
+    switch profileType {
+    case "cpu":
+        pprof.StopCPUProfile()
+    default:
+        if p := pprof.Lookup(profileType); p != nil {
+            p.WriteTo(buf, 0)
+        }
+    }
+
+    profileBuf = nil
+    profileType = ""
+    log.Println("Stopped profile")
+    return buf.Bytes()
+}
+
 func main() {
+    // mux (rather than http.DefaultServeMux) so the additional transport
+    // listeners below - h2c, http3, unix, grpc - can all serve the exact
+    // same routes, keeping /control/* metrics shared across every backend.
+    mux := http.NewServeMux()
+
     // Endpoint principal
-    http.HandleFunc("/", handleRequest)
-    
+    mux.HandleFunc("/", handleRequest)
+
     // Endpoint para controle do benchmark
-    http.HandleFunc("/control/start-collection", func(w http.ResponseWriter, r *http.Request) {
+    mux.HandleFunc("/control/start-collection", func(w http.ResponseWriter, r *http.Request) {
         atomic.StoreInt32(&collectMetrics, 1)
-        metrics.StartTime = time.Now()
+        metrics.setStartTime(time.Now())
         log.Println("Started collecting metrics")
         w.WriteHeader(http.StatusOK)
     })
-    
-    http.HandleFunc("/control/stop-collection", func(w http.ResponseWriter, r *http.Request) {
+
+    mux.HandleFunc("/control/stop-collection", func(w http.ResponseWriter, r *http.Request) {
         atomic.StoreInt32(&collectMetrics, 0)
         log.Println("Stopped collecting metrics")
         w.WriteHeader(http.StatusOK)
     })
-    
-    http.HandleFunc("/control/reset", func(w http.ResponseWriter, r *http.Request) {
-        metrics.mu.Lock()
-        metrics.TotalRequests = 0
-        metrics.TotalErrors = 0
-        metrics.Latencies = make([]float64, 0)
-        metrics.StartTime = time.Now()
-        metrics.mu.Unlock()
+
+    mux.HandleFunc("/control/reset", func(w http.ResponseWriter, r *http.Request) {
+        metrics.reset()
         log.Println("Metrics reset")
         w.WriteHeader(http.StatusOK)
     })
-    
-    http.HandleFunc("/control/metrics", func(w http.ResponseWriter, r *http.Request) {
-        metrics.mu.Lock()
-        defer metrics.mu.Unlock()
-        
+
+    mux.HandleFunc("/control/metrics", func(w http.ResponseWriter, r *http.Request) {
         w.Header().Set("Content-Type", "application/json")
-        json.NewEncoder(w).Encode(metrics)
+        json.NewEncoder(w).Encode(metrics.snapshot())
+    })
+
+    mux.HandleFunc("/control/profile/start", func(w http.ResponseWriter, r *http.Request) {
+        profType := r.URL.Query().Get("type")
+        if profType == "" {
+            profType = "cpu"
+        }
+        seconds, _ := strconv.Atoi(r.URL.Query().Get("seconds"))
+
+        profileMu.Lock()
+        defer profileMu.Unlock()
+
+        if profileBuf != nil {
+            http.Error(w, "profile already in progress", http.StatusConflict)
+            return
+        }
+
+        buf := &bytes.Buffer{}
+        if profType == "cpu" {
+            if err := pprof.StartCPUProfile(buf); err != nil {
+                http.Error(w, err.Error(), http.StatusInternalServerError)
+                return
+            }
+        } else if pprof.Lookup(profType) == nil {
+            http.Error(w, "unknown profile type: "+profType, http.StatusBadRequest)
+            return
+        }
+
+        profileBuf = buf
+        profileType = profType
+        completedProfile = nil // superseded by this run
+        log.Printf("Started %s profile", profType)
+
+        if seconds > 0 {
+            profileStop = time.AfterFunc(time.Duration(seconds)*time.Second, func() {
+                profileMu.Lock()
+                defer profileMu.Unlock()
+                if profileBuf != nil {
+                    // Stash the bytes instead of discarding them: the caller
+                    // gave us `seconds` instead of calling /control/profile/stop
+                    // itself, but still needs somewhere to collect the profile from.
+                    completedProfile = stopProfileLocked()
+                    log.Printf("Auto-stopped %s profile after %ds, awaiting /control/profile/stop", profType, seconds)
+                }
+            })
+        }
+
+        w.WriteHeader(http.StatusOK)
     })
-    
-    http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+
+    mux.HandleFunc("/control/profile/stop", func(w http.ResponseWriter, r *http.Request) {
+        profileMu.Lock()
+        defer profileMu.Unlock()
+
+        if profileBuf == nil {
+            if completedProfile == nil {
+                http.Error(w, "no profile in progress", http.StatusConflict)
+                return
+            }
+            // The `seconds` auto-stop already finished this one; serve what
+            // it captured instead of erroring just because we got here late.
+            data := completedProfile
+            completedProfile = nil
+            w.Header().Set("Content-Type", "application/octet-stream")
+            w.Write(data)
+            return
+        }
+
+        data := stopProfileLocked()
+        w.Header().Set("Content-Type", "application/octet-stream")
+        w.Write(data)
+    })
+
+    mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
         w.WriteHeader(http.StatusOK)
         w.Write([]byte(`{"status":"ok"}`))
     })
 
+    sampleIntervalMs, _ := strconv.Atoi(os.Getenv("RUNTIME_SAMPLE_INTERVAL_MS"))
+    if sampleIntervalMs <= 0 {
+        sampleIntervalMs = 1000
+    }
+    startRuntimeSampler(time.Duration(sampleIntervalMs) * time.Millisecond)
+
+    // h2c/http3/unix/grpc listeners, one per protocol the chunk0-6 bench
+    // harness compares, each opt-in via its own env var.
+    startAdditionalListeners(mux)
+
     port := os.Getenv("PORT")
     if port == "" {
         port = "8080"
     }
 
     log.Printf("Server starting on port %s", port)
-    log.Fatal(http.ListenAndServe(":"+port, nil))
+    log.Fatal(http.ListenAndServe(":"+port, mux))
 }
 
 func handleRequest(w http.ResponseWriter, r *http.Request) {
     start := time.Now()
-    
-    // Simula processamento mínimo
+
+    echo := isEcho(r)
+
+    var body []byte
+    if echo {
+        // Echo mode: the response IS the request body, so req_size actually
+        // drives the response's wire cost too, instead of only the resp_size
+        // knob.
+        body, _ = io.ReadAll(r.Body)
+    } else {
+        // Read the full request body so its size is actually on the wire cost,
+        // not just in the client's send buffer.
+        io.Copy(io.Discard, r.Body)
+        body = respPayload(r)
+    }
+
     w.Header().Set("Content-Type", "application/json")
     w.WriteHeader(http.StatusOK)
-    w.Write([]byte(`{"msg":"ok"}`))
-    
+    w.Write(body)
+
     // Coleta métricas apenas se estiver na fase de execução
     if atomic.LoadInt32(&collectMetrics) == 1 {
-        latency := float64(time.Since(start).Microseconds()) / 1000.0 // em ms
-        
-        atomic.AddInt64(&metrics.TotalRequests, 1)
-        
-        metrics.mu.Lock()
-        metrics.Latencies = append(metrics.Latencies, latency)
-        metrics.mu.Unlock()
+        metrics.record(time.Since(start).Microseconds())
+    }
+}
+
+// isEcho reports whether the request asked for echo mode via the echo query
+// param or X-Echo header (any value other than empty/"0"/"false").
+func isEcho(r *http.Request) bool {
+    v := r.URL.Query().Get("echo")
+    if v == "" {
+        v = r.Header.Get("X-Echo")
+    }
+    return v != "" && v != "0" && v != "false"
+}
+
+// respPayload returns the response body for a request, honoring an explicit
+// size via the resp_size query param or X-Resp-Size header. The payload is
+// a deterministic repeating pattern so its bytes are cheap to generate but
+// still cross the wire like real response data.
+func respPayload(r *http.Request) []byte {
+    sizeStr := r.URL.Query().Get("resp_size")
+    if sizeStr == "" {
+        sizeStr = r.Header.Get("X-Resp-Size")
+    }
+    size, err := strconv.Atoi(sizeStr)
+    if err != nil {
+        size = 0
     }
+    return respPayloadBytes(size)
 }
\ No newline at end of file
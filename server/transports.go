@@ -0,0 +1,108 @@
+package main
+
+import (
+	"crypto/tls"
+	"log"
+	"net"
+	"net/http"
+	"os"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"github.com/quic-go/quic-go/http3"
+	"google.golang.org/grpc"
+
+	"github.com/sydo26/tcc2-rps-bench/pkg/pingpb"
+)
+
+// startAdditionalListeners brings up every transport the chunk0-6 protocol
+// comparison needs, each gated behind its own env var so a plain HTTP/1.1
+// deployment (the default) doesn't pay for TLS certs or a QUIC stack it
+// isn't using. All of them serve the same mux, so /control/* metrics are
+// shared across backends regardless of which transport a request arrived on.
+func startAdditionalListeners(mux *http.ServeMux) {
+	if port := os.Getenv("H2C_PORT"); port != "" {
+		go serveH2C(mux, port)
+	}
+	if port := os.Getenv("HTTP3_PORT"); port != "" {
+		go serveHTTP3(mux, port)
+	}
+	if sockPath := os.Getenv("UNIX_SOCKET_PATH"); sockPath != "" {
+		go serveUnix(mux, sockPath)
+	}
+	if port := os.Getenv("GRPC_PORT"); port != "" {
+		go serveGRPC(port)
+	}
+}
+
+// serveH2C runs cleartext HTTP/2 ("h2c" - HTTP/2 with prior knowledge, no
+// TLS) on its own port, so the nethttp2 backend can be benchmarked without
+// needing a cert.
+func serveH2C(mux *http.ServeMux, port string) {
+	h2s := &http2.Server{}
+	handler := h2c.NewHandler(mux, h2s)
+	log.Printf("h2c listener starting on port %s", port)
+	if err := http.ListenAndServe(":"+port, handler); err != nil {
+		log.Printf("h2c listener stopped: %v", err)
+	}
+}
+
+// serveHTTP3 runs an HTTP/3 (QUIC) listener. Unlike h2c, HTTP/3 requires
+// TLS at the protocol level, so a throwaway self-signed cert is generated
+// for it - fine for a benchmark harness that never leaves localhost/the
+// test network.
+func serveHTTP3(mux *http.ServeMux, port string) {
+	cert, err := selfSignedCert()
+	if err != nil {
+		log.Printf("http3 listener disabled: generating cert: %v", err)
+		return
+	}
+
+	srv := &http3.Server{
+		Addr:      ":" + port,
+		Handler:   mux,
+		TLSConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+	}
+	log.Printf("http3 listener starting on port %s", port)
+	if err := srv.ListenAndServe(); err != nil {
+		log.Printf("http3 listener stopped: %v", err)
+	}
+}
+
+// serveUnix runs the same handler over a Unix domain socket, for the unix
+// backend. The socket path is removed first since net.Listen("unix", ...)
+// fails if a stale file from a previous run is still there.
+func serveUnix(mux *http.ServeMux, sockPath string) {
+	os.Remove(sockPath)
+
+	ln, err := net.Listen("unix", sockPath)
+	if err != nil {
+		log.Printf("unix socket listener disabled: %v", err)
+		return
+	}
+
+	log.Printf("unix socket listener starting on %s", sockPath)
+	if err := http.Serve(ln, mux); err != nil {
+		log.Printf("unix socket listener stopped: %v", err)
+	}
+}
+
+// serveGRPC runs the PingService used by the grpc backend. It uses the
+// JSON codec from pkg/pingpb instead of protobuf, so no protoc step is
+// needed to keep client and server in sync.
+func serveGRPC(port string) {
+	ln, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		log.Printf("grpc listener disabled: %v", err)
+		return
+	}
+
+	grpcServer := grpc.NewServer(grpc.ForceServerCodec(pingpb.JSONCodec{}))
+	grpcServer.RegisterService(&pingServiceDesc, &pingServer{})
+
+	log.Printf("grpc listener starting on port %s", port)
+	if err := grpcServer.Serve(ln); err != nil {
+		log.Printf("grpc listener stopped: %v", err)
+	}
+}